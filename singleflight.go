@@ -3,14 +3,22 @@ package singleflight
 import "sync"
 
 type (
+	// Result 是 DoChan 投递给每个等待者的结果，Shared 表示该结果是否被多个调用方共享
+	Result[T any] struct {
+		Val    T
+		Err    error
+		Shared bool
+	}
 
 	// SingleFlight
 	// 可以将对同一个 Key 的并发请求进行合并，只让其中一个请求到数据库进行查询，其他请求共享同一个结果，可以很大程度提升并发能力
 	// 定义 call 的结构
 	call[T any] struct {
-		wg  sync.WaitGroup // 用于实现通过1个 call，其他 call 阻塞
-		val T              // 表示 call 操作的返回结果
-		err error          // 表示 call 操作发生的错误
+		wg    sync.WaitGroup   // 用于实现通过1个 call，其他 call 阻塞
+		val   T                // 表示 call 操作的返回结果
+		err   error            // 表示 call 操作发生的错误
+		chans []chan Result[T] // DoChan 的等待者，makeCall 完成后逐一广播结果
+		dups  int              // 除发起者外，有多少个协程加入了这次 call，供 ForgetUnshared 判断是否存在等待者
 	}
 
 	// 总控结构，实现 SingleFlight 接口
@@ -52,12 +60,66 @@ func (g *flightGroup[T]) DoEx(key string, fn func() (T, error)) (val T, fresh bo
 	return c.val, true, c.err
 }
 
+// DoChan 与 Do 类似，但不会阻塞调用方，而是立即返回一个 channel，
+// fn 执行完成后结果会被投递到该 channel 中（只投递一次）。
+// 调用方可以配合 select 实现超时或取消，而不必像 Do 那样独占一个 goroutine 等待。
+func (g *flightGroup[T]) DoChan(key string, fn func() (T, error)) <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+
+	g.lock.Lock()
+	if c, ok := g.calls[key]; ok {
+		// 已经有协程在执行同一个 key，把 ch 挂到它的等待者列表上，等待结果广播
+		c.dups++
+		c.chans = append(c.chans, ch)
+		g.lock.Unlock()
+		return ch
+	}
+
+	c := new(call[T])
+	c.wg.Add(1)
+	c.chans = append(c.chans, ch)
+	g.calls[key] = c
+	g.lock.Unlock()
+
+	go g.makeCall(c, key, fn)
+
+	return ch
+}
+
+// Forget 无条件地将 key 从 g.calls 中移除，即使对应的 call 仍在执行中，
+// 这样下一次 Do/DoEx/DoChan 会重新发起一次全新的 fn 调用。
+// 适用于调用方提前得知当前 in-flight 的结果即将过期或失败，需要立刻触发重试的场景。
+func (g *flightGroup[T]) Forget(key string) {
+	g.lock.Lock()
+	delete(g.calls, key)
+	g.lock.Unlock()
+}
+
+// ForgetUnshared 只有在没有其他协程加入这次 call 时才会移除 key，并返回 true；
+// 如果已经有协程在等待这次 call 的结果（c.dups > 0），则保留 key 并返回 false，
+// 避免把正在被共享的结果连同等待者一起丢弃。
+func (g *flightGroup[T]) ForgetUnshared(key string) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	c, ok := g.calls[key]
+	if !ok {
+		return true
+	}
+	if c.dups > 0 {
+		return false
+	}
+	delete(g.calls, key)
+	return true
+}
+
 func (g *flightGroup[T]) createCall(key string) (c *call[T], done bool) {
 	g.lock.Lock()
 	// 先看第一步：判断是第一个请求的协程（利用 map）此处判断 map 中的 key 是否存在，
 	// 如果已经存在，说明已经有其他协程在请求了，
 	// 当前这个协程只需要等待，等待是利用了 sync.WaitGroup 的 Wait() 方法实现的，此处还是很巧妙的
 	if c, ok := g.calls[key]; ok {
+		c.dups++
 		g.lock.Unlock()
 		c.wg.Wait()
 		return c, true
@@ -77,13 +139,20 @@ func (g *flightGroup[T]) createCall(key string) (c *call[T], done bool) {
 }
 
 func (g *flightGroup[T]) makeCall(c *call[T], key string, fn func() (T, error)) {
-	// 这个方法中做的事情很简单，就是执行了传递的匿名函数 fn()（也就是真正 call 请求要做的事情）。最后处理收尾的事情（通过 defer），也是分成两步：
+	// 这个方法中做的事情很简单，就是执行了传递的匿名函数 fn()（也就是真正 call 请求要做的事情）。最后处理收尾的事情（通过 defer），也是分成三步：
 	//
 	//删除 map 中的 key，使得下次发起请求可以获取新的值。
+	//把结果广播给所有通过 DoChan 等待的 channel。
 	//调用 wg.Done()，让之前阻塞的协程全部获得结果并返回。
 	defer func() {
 		g.lock.Lock()
 		delete(g.calls, key)
+		// c.dups 统计了所有加入这次 call 的协程（不论是通过 Do/DoEx 还是 DoChan），
+		// 只要有人加入过，这次结果就是被共享的，不能只看 c.chans 的长度。
+		shared := c.dups > 0
+		for _, ch := range c.chans {
+			ch <- Result[T]{Val: c.val, Err: c.err, Shared: shared}
+		}
 		g.lock.Unlock()
 		c.wg.Done()
 	}()
@@ -9,7 +9,6 @@ import (
 	"time"
 )
 
-//
 // TestExclusiveCallDo 独自执行Do函数
 func TestExclusiveCallDo(t *testing.T) {
 	g := NewSingleFlight[interface{}]()
@@ -24,7 +23,6 @@ func TestExclusiveCallDo(t *testing.T) {
 	}
 }
 
-//
 // TestExclusiveCallDoErr 独自执行Do函数，并返回错误
 func TestExclusiveCallDoErr(t *testing.T) {
 	g := NewSingleFlight[interface{}]()
@@ -41,8 +39,7 @@ func TestExclusiveCallDoErr(t *testing.T) {
 	}
 }
 
-//
-//  TestExclusiveCallDoDupSuppress 在独自执行Do函数时，如果发生了重复调用，则不会触发Do函数
+// TestExclusiveCallDoDupSuppress 在独自执行Do函数时，如果发生了重复调用，则不会触发Do函数
 func TestExclusiveCallDoDupSuppress(t *testing.T) {
 	g := NewSingleFlight[string]()
 	c := make(chan string)
@@ -77,8 +74,7 @@ func TestExclusiveCallDoDupSuppress(t *testing.T) {
 	}
 }
 
-//
-//  TestExclusiveCallDoDiffDupSuppress 在独自执行Do函数时，如果发生了重复调用，但是key不同，则会触发Do函数
+// TestExclusiveCallDoDiffDupSuppress 在独自执行Do函数时，如果发生了重复调用，但是key不同，则会触发Do函数
 func TestExclusiveCallDoDiffDupSuppress(t *testing.T) {
 	g := NewSingleFlight[interface{}]()
 	broadcast := make(chan struct{})
@@ -112,8 +108,7 @@ func TestExclusiveCallDoDiffDupSuppress(t *testing.T) {
 	}
 }
 
-//
-//  TestExclusiveCallDoExDupSuppress 在独自执行DoEx函数时，如果发生了重复调用，则不会触发Do函数
+// TestExclusiveCallDoExDupSuppress 在独自执行DoEx函数时，如果发生了重复调用，则不会触发Do函数
 func TestExclusiveCallDoExDupSuppress(t *testing.T) {
 	g := NewSingleFlight[interface{}]()
 	c := make(chan string)
@@ -152,3 +147,208 @@ func TestExclusiveCallDoExDupSuppress(t *testing.T) {
 		t.Errorf("freshes = %d; want 1", got)
 	}
 }
+
+// TestDoChan 独自执行 DoChan 函数，直接从返回的 channel 中获取结果
+func TestDoChan(t *testing.T) {
+	g := NewSingleFlight[interface{}]()
+	res := <-g.DoChan("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+	if got, want := fmt.Sprintf("%v (%T)", res.Val, res.Val), "bar (string)"; got != want {
+		t.Errorf("DoChan = %v; want %v", got, want)
+	}
+	if res.Err != nil {
+		t.Errorf("DoChan error = %v", res.Err)
+	}
+}
+
+// TestDoChanDupSuppress 多个 DoChan 针对同一个 key 调用时，fn 只会被执行一次，
+// 且所有 channel 都会收到相同的结果，Shared 为 true
+func TestDoChanDupSuppress(t *testing.T) {
+	g := NewSingleFlight[string]()
+	c := make(chan string)
+	var calls int32
+	fn := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return <-c, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	chans := make([]<-chan Result[string], n)
+	for i := 0; i < n; i++ {
+		chans[i] = g.DoChan("key", fn)
+	}
+	time.Sleep(100 * time.Millisecond) // let goroutines above block
+	c <- "bar"
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(ch <-chan Result[string]) {
+			defer wg.Done()
+			res := <-ch
+			if res.Err != nil {
+				t.Errorf("DoChan error: %v", res.Err)
+			}
+			if res.Val != "bar" {
+				t.Errorf("got %q; want %q", res.Val, "bar")
+			}
+			if !res.Shared {
+				t.Errorf("Shared = false; want true")
+			}
+		}(chans[i])
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("number of calls = %d; want 1", got)
+	}
+}
+
+// TestDoChanSharedWithDoJoiner Shared 应该反映所有加入这次 call 的协程，
+// 而不仅仅是通过 DoChan 加入的协程数量：这里 leader 走 DoChan，joiner 走 Do
+func TestDoChanSharedWithDoJoiner(t *testing.T) {
+	g := NewSingleFlight[string]()
+	c := make(chan string)
+	fn := func() (string, error) {
+		return <-c, nil
+	}
+
+	ch := g.DoChan("key", fn)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := g.Do("key", fn)
+		if err != nil {
+			t.Errorf("Do error: %v", err)
+		}
+		if v != "bar" {
+			t.Errorf("got %q; want %q", v, "bar")
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the Do call join the DoChan call
+	c <- "bar"
+	wg.Wait()
+
+	res := <-ch
+	if res.Err != nil {
+		t.Errorf("DoChan error: %v", res.Err)
+	}
+	if res.Val != "bar" {
+		t.Errorf("got %q; want %q", res.Val, "bar")
+	}
+	if !res.Shared {
+		t.Errorf("Shared = false; want true, a Do caller joined the in-flight DoChan call")
+	}
+}
+
+// TestForgetUnshared 在没有其他协程加入这次 call 时，ForgetUnshared 应该成功移除 key 并返回 true
+func TestForgetUnshared(t *testing.T) {
+	g := NewSingleFlight[string]()
+
+	var err error
+	done := make(chan struct{})
+	go func() {
+		_, err = g.Do("key", func() (string, error) {
+			time.Sleep(50 * time.Millisecond)
+			return "bar", nil
+		})
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond) // let the goroutine above start the call
+
+	if !g.ForgetUnshared("key") {
+		t.Errorf("ForgetUnshared = false; want true, no other caller has joined yet")
+	}
+
+	<-done
+	if err != nil {
+		t.Errorf("Do error = %v", err)
+	}
+}
+
+// TestForgetUnsharedWithWaiters 如果已经有其他协程加入了这次 call（还在等待结果），
+// ForgetUnshared 应该返回 false，且不会移除 key
+func TestForgetUnsharedWithWaiters(t *testing.T) {
+	g := NewSingleFlight[string]()
+	c := make(chan string)
+	fn := func() (string, error) {
+		return <-c, nil
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		g.Do("key", fn)
+	}()
+	go func() {
+		defer wg.Done()
+		g.Do("key", fn)
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let both goroutines above join the same call
+
+	if g.ForgetUnshared("key") {
+		t.Errorf("ForgetUnshared = true; want false, a waiter has already joined")
+	}
+
+	c <- "bar"
+	wg.Wait()
+}
+
+// TestForget 调用 Forget 后，即使原来的 call 还在执行，后续的 Do 也会重新触发一次 fn
+func TestForget(t *testing.T) {
+	g := NewSingleFlight[string]()
+	c1 := make(chan string)
+	c2 := make(chan string)
+	var calls int32
+	fn := func(c <-chan string) func() (string, error) {
+		return func() (string, error) {
+			atomic.AddInt32(&calls, 1)
+			return <-c, nil
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := g.Do("key", fn(c1))
+		if err != nil {
+			t.Errorf("Do error: %v", err)
+		}
+		if v != "bar" {
+			t.Errorf("got %q; want %q", v, "bar")
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the first call start and block on c1
+	g.Forget("key")
+
+	var v2 string
+	var err2 error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v2, err2 = g.Do("key", fn(c2))
+	}()
+
+	time.Sleep(50 * time.Millisecond) // let the second call start its own fn and block on c2
+	c2 <- "baz"                       // unblocks the second (and newest) call
+	c1 <- "bar"                       // unblocks the first call
+	wg.Wait()
+
+	if err2 != nil {
+		t.Errorf("Do error: %v", err2)
+	}
+	if v2 != "baz" {
+		t.Errorf("got %q; want %q", v2, "baz")
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("number of calls = %d; want 2", got)
+	}
+}